@@ -0,0 +1,121 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Archiver streams every fetched request/response pair to a gzipped
+// WARC 1.1 file, in the format popularized by the Internet Archive.
+type Archiver struct {
+	mu   sync.Mutex
+	file *os.File
+	gz   *gzip.Writer
+}
+
+// NewArchiver creates a new Archiver that writes WARC records to path.
+// The file is created (or truncated) and gzip-compressed as records
+// are appended.
+func NewArchiver(path string) (*Archiver, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Archiver{
+		file: f,
+		gz:   gzip.NewWriter(f),
+	}, nil
+}
+
+// Record writes a request/response pair as a pair of WARC records.
+// body is the already-read response body, since resp.Body may have
+// been consumed by the time the caller archives it.
+func (a *Archiver) Record(req *http.Request, resp *http.Response, body []byte) error {
+	reqBytes, err := httputil.DumpRequestOut(req.Clone(req.Context()), true)
+	if err != nil {
+		return err
+	}
+
+	respBytes, err := dumpResponse(resp, body)
+	if err != nil {
+		return err
+	}
+
+	date := time.Now().UTC().Format("2006-01-02T15:04:05Z")
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.writeRecord("request", req.URL.String(), date, reqBytes); err != nil {
+		return err
+	}
+	return a.writeRecord("response", req.URL.String(), date, respBytes)
+}
+
+// writeRecord emits a single WARC record. Callers must hold a.mu, since
+// WARC writes must be serialized so concurrent workers don't interleave.
+func (a *Archiver) writeRecord(warcType, targetURI, date string, block []byte) error {
+	digest := sha1.Sum(block)
+	header := fmt.Sprintf(
+		"WARC/1.1\r\n"+
+			"WARC-Type: %s\r\n"+
+			"WARC-Target-URI: %s\r\n"+
+			"WARC-Date: %s\r\n"+
+			"WARC-Record-ID: <urn:uuid:%s>\r\n"+
+			"Content-Type: application/http; msgtype=%s\r\n"+
+			"WARC-Block-Digest: sha1:%s\r\n"+
+			"Content-Length: %d\r\n"+
+			"\r\n",
+		warcType, targetURI, date, uuid.New().String(), warcType,
+		base32.StdEncoding.EncodeToString(digest[:]), len(block))
+
+	if _, err := a.gz.Write([]byte(header)); err != nil {
+		return err
+	}
+	if _, err := a.gz.Write(block); err != nil {
+		return err
+	}
+	_, err := a.gz.Write([]byte("\r\n\r\n"))
+	return err
+}
+
+// dumpResponse serializes a response's status line, headers, and body into
+// the raw HTTP wire format expected inside a WARC response record. The
+// Content-Length header is overwritten to match the archived body rather
+// than trusted from the origin response, since middleware (decompression,
+// in particular) may have already changed body into something a different
+// length than what the origin server declared.
+func dumpResponse(resp *http.Response, body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "HTTP/%d.%d %s\r\n", resp.ProtoMajor, resp.ProtoMinor, resp.Status)
+
+	header := resp.Header.Clone()
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	if err := header.Write(&buf); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\r\n")
+	buf.Write(body)
+	return buf.Bytes(), nil
+}
+
+// Close flushes and closes the underlying WARC file.
+func (a *Archiver) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.gz.Close(); err != nil {
+		return err
+	}
+	return a.file.Close()
+}
@@ -0,0 +1,198 @@
+package crawler
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	bucketFrontier = []byte("frontier") // seq -> frontierEntry
+	bucketSeen     = []byte("seen")     // url -> depth
+	bucketDone     = []byte("done")     // url -> status
+	bucketInFlight = []byte("inflight") // url -> frontierEntry, dequeued but not yet MarkDone
+)
+
+// BoltQueue is a Queue backed by an embedded bbolt database, so a crawl's
+// frontier survives interruption. Reopening the same state directory
+// resumes any URL that was enqueued but never marked done.
+type BoltQueue struct {
+	db *bolt.DB
+}
+
+// NewBoltQueue opens (or creates) a bbolt database under dir.
+func NewBoltQueue(dir string) (*BoltQueue, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(filepath.Join(dir, "crawl.db"), 0o644, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{bucketFrontier, bucketSeen, bucketDone, bucketInFlight} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		// Anything still in the in-flight bucket was dequeued by a
+		// previous run that never reached MarkDone (killed mid-fetch, or
+		// crashed). Put it back on the frontier so it resumes instead of
+		// silently vanishing.
+		return requeueInFlight(tx)
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &BoltQueue{db: db}, nil
+}
+
+// requeueInFlight moves every entry left in the in-flight bucket back onto
+// the frontier.
+func requeueInFlight(tx *bolt.Tx) error {
+	inFlight := tx.Bucket(bucketInFlight)
+	frontier := tx.Bucket(bucketFrontier)
+
+	var stale [][]byte
+	c := inFlight.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		seq, err := frontier.NextSequence()
+		if err != nil {
+			return err
+		}
+		if err := frontier.Put(itob(seq), append([]byte(nil), v...)); err != nil {
+			return err
+		}
+		stale = append(stale, append([]byte(nil), k...))
+	}
+	for _, k := range stale {
+		if err := inFlight.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Enqueue adds url to the frontier at depth, unless it has already been seen.
+func (q *BoltQueue) Enqueue(url string, depth int, tag LinkTag) (bool, error) {
+	added := false
+	err := q.db.Update(func(tx *bolt.Tx) error {
+		seen := tx.Bucket(bucketSeen)
+		if seen.Get([]byte(url)) != nil {
+			return nil
+		}
+
+		entry, err := json.Marshal(frontierEntry{URL: url, Depth: depth, Tag: tag})
+		if err != nil {
+			return err
+		}
+
+		frontier := tx.Bucket(bucketFrontier)
+		seq, err := frontier.NextSequence()
+		if err != nil {
+			return err
+		}
+		if err := frontier.Put(itob(seq), entry); err != nil {
+			return err
+		}
+		if err := seen.Put([]byte(url), itob(uint64(depth))); err != nil {
+			return err
+		}
+		added = true
+		return nil
+	})
+	return added, err
+}
+
+// Dequeue removes the oldest pending URL from the frontier and returns it,
+// moving it into the in-flight bucket until MarkDone is called. A fast,
+// read-only check skips the write transaction entirely when the frontier
+// is empty, so idle workers polling every 25ms don't serialize on bbolt's
+// single writer lock.
+func (q *BoltQueue) Dequeue() (string, int, LinkTag, bool, error) {
+	empty := true
+	q.db.View(func(tx *bolt.Tx) error {
+		k, _ := tx.Bucket(bucketFrontier).Cursor().First()
+		empty = k == nil
+		return nil
+	})
+	if empty {
+		return "", 0, TagPrimary, false, nil
+	}
+
+	var entry frontierEntry
+	ok := false
+
+	err := q.db.Update(func(tx *bolt.Tx) error {
+		frontier := tx.Bucket(bucketFrontier)
+		c := frontier.Cursor()
+		k, v := c.First()
+		if k == nil {
+			return nil
+		}
+		if err := json.Unmarshal(v, &entry); err != nil {
+			return err
+		}
+		ok = true
+
+		if err := tx.Bucket(bucketInFlight).Put([]byte(entry.URL), v); err != nil {
+			return err
+		}
+		return frontier.Delete(k)
+	})
+	if err != nil {
+		return "", 0, TagPrimary, false, err
+	}
+	return entry.URL, entry.Depth, entry.Tag, ok, nil
+}
+
+// MarkDone records the final status code for url and clears it from the
+// in-flight bucket, so it isn't resumed on restart.
+func (q *BoltQueue) MarkDone(url string, status int) error {
+	return q.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(bucketInFlight).Delete([]byte(url)); err != nil {
+			return err
+		}
+		return tx.Bucket(bucketDone).Put([]byte(url), itob(uint64(status)))
+	})
+}
+
+// Seen reports whether url has already been enqueued.
+func (q *BoltQueue) Seen(url string) bool {
+	seen := false
+	q.db.View(func(tx *bolt.Tx) error {
+		seen = tx.Bucket(bucketSeen).Get([]byte(url)) != nil
+		return nil
+	})
+	return seen
+}
+
+// Pending returns the number of URLs currently sitting in the frontier.
+func (q *BoltQueue) Pending() (int, error) {
+	count := 0
+	err := q.db.View(func(tx *bolt.Tx) error {
+		count = tx.Bucket(bucketFrontier).Stats().KeyN
+		return nil
+	})
+	return count, err
+}
+
+// Close releases the underlying bbolt database.
+func (q *BoltQueue) Close() error {
+	return q.db.Close()
+}
+
+// itob encodes v as a big-endian byte slice, so bbolt's byte-order key
+// comparison also orders the sequence numerically.
+func itob(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
@@ -1,9 +1,14 @@
 package crawler
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/time/rate"
@@ -12,9 +17,17 @@ import (
 // Client represents our custom HTTP client with rate limiting and configurable options
 type Client struct {
 	httpClient  *http.Client
-	rateLimiter *rate.Limiter
+	baseRPS     float64
+	limiterMu   sync.Mutex
+	limiters    map[string]*rate.Limiter // per host, so Crawl-delay only throttles its own host
+	robots      *RobotsPolicy            // optional, consulted for Crawl-delay
 	headers     map[string]string
 	baseURL     *url.URL
+	archiver    *Archiver
+	scopePolicy ScopePolicy
+	scopeRegexp *regexp.Regexp
+	middlewares []Middleware
+	handler     Handler
 }
 
 // ClientConfig holds configuration options for the HTTP client
@@ -24,6 +37,11 @@ type ClientConfig struct {
 	Headers           map[string]string
 	BaseURL           string
 	RequestsPerSecond float64
+	Archiver          *Archiver   // optional, tees requests/responses to a WARC file
+	ScopePolicy       ScopePolicy // defaults to SameHost
+	ScopeRegexp       string      // pattern used when ScopePolicy is Regexp
+	RespectRobots     bool        // enforce robots.txt Disallow/Allow and Crawl-delay
+	UserAgentToken    string      // user-agent token matched against robots.txt groups; defaults to the User-Agent header
 }
 
 // NewClient creates a new custom HTTP client with the given configuration
@@ -41,49 +59,154 @@ func NewClient(config ClientConfig) (*Client, error) {
 		TLSHandshakeTimeout: 10 * time.Second,
 	}
 
-	// Create HTTP client with custom transport
+	// Create HTTP client with custom transport. Redirects are never
+	// followed here: CheckRedirect always stops at the first hop so that
+	// RedirectMiddleware — not net/http — owns hop-following. Otherwise
+	// net/http would auto-follow every hop within MaxRedirects internally
+	// and return only the final response, and no registered middleware
+	// (cookies, robots, dedup) would ever see the intermediate ones.
 	httpClient := &http.Client{
 		Transport: transport,
 		Timeout:   config.Timeout,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			if len(via) >= config.MaxRedirects {
-				return http.ErrUseLastResponse
-			}
-			return nil
+			return http.ErrUseLastResponse
 		},
 	}
 
-	// Create rate limiter with a minimum of 1 request per second
+	// Use a minimum of 1 request per second as the default ceiling; a
+	// host's robots.txt Crawl-delay can only ever slow an individual host
+	// down from there, never speed it up.
 	rps := config.RequestsPerSecond
 	if rps < 1.0 {
 		rps = 1.0
 	}
-	// Create a limiter with a burst of 1 to ensure strict rate limiting
-	limiter := rate.NewLimiter(rate.Limit(rps), 1)
 
-	return &Client{
+	var scopeRegexp *regexp.Regexp
+	if config.ScopePolicy == Regexp && config.ScopeRegexp != "" {
+		scopeRegexp, err = regexp.Compile(config.ScopeRegexp)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c := &Client{
 		httpClient:  httpClient,
-		rateLimiter: limiter,
+		baseRPS:     rps,
+		limiters:    make(map[string]*rate.Limiter),
 		headers:     config.Headers,
 		baseURL:     baseURL,
-	}, nil
+		archiver:    config.Archiver,
+		scopePolicy: config.ScopePolicy,
+		scopeRegexp: scopeRegexp,
+	}
+	c.handler = c.doRequest
+
+	// Wire the built-in middlewares every client gets by default: bodies
+	// are transparently decompressed and a URL is never fetched twice in
+	// the same crawl. Robots enforcement is opt-in (it can reject
+	// requests the caller explicitly asked for). RedirectMiddleware
+	// wraps robots enforcement and CookieJarMiddleware (registered after
+	// it, so they're nested inside the redirect loop), so every 3xx hop is
+	// robots-checked and a Set-Cookie on an intermediate hop — the normal
+	// login -> redirect -> session-cookie flow — is captured, not just the
+	// final response's.
+	var defaultMiddlewares []Middleware
+	defaultMiddlewares = append(defaultMiddlewares,
+		DecompressionMiddleware(),
+		RedirectMiddleware(config.MaxRedirects),
+	)
+
+	if config.RespectRobots {
+		userAgent := config.UserAgentToken
+		if userAgent == "" {
+			userAgent = config.Headers["User-Agent"]
+		}
+		// RobotsPolicy fetches robots.txt by URL string; adapt it onto
+		// doRequest so the fetch itself bypasses rate limiting and the
+		// rest of the middleware chain.
+		c.robots = NewRobotsPolicy(userAgent, func(ctx context.Context, urlStr string) (*http.Response, error) {
+			req, err := http.NewRequestWithContext(ctx, "GET", urlStr, nil)
+			if err != nil {
+				return nil, err
+			}
+			return c.doRequest(ctx, req)
+		})
+		defaultMiddlewares = append(defaultMiddlewares, c.robots.Middleware())
+	}
+
+	defaultMiddlewares = append(defaultMiddlewares,
+		CookieJarMiddleware(NewCookieJar()),
+		DedupMiddleware(),
+	)
+	c.Use(defaultMiddlewares...)
+
+	return c, nil
 }
 
-// Get performs a GET request with rate limiting and custom headers
-func (c *Client) Get(ctx context.Context, urlStr string) (*http.Response, error) {
-	// Wait for rate limiter with the provided context
-	if err := c.rateLimiter.Wait(ctx); err != nil {
-		return nil, err
+// limiterFor returns the rate limiter for host, creating it on first use.
+// Its rate is min(RequestsPerSecond, 1/Crawl-delay) when a RobotsPolicy is
+// configured and reports a Crawl-delay for that host.
+func (c *Client) limiterFor(ctx context.Context, urlStr, host string) *rate.Limiter {
+	c.limiterMu.Lock()
+	limiter, ok := c.limiters[host]
+	c.limiterMu.Unlock()
+	if ok {
+		return limiter
+	}
+
+	// CrawlDelay may block on a robots.txt fetch for this host; resolve it
+	// before taking limiterMu, so it only ever guards the map itself and
+	// workers touching other hosts aren't serialized behind the round trip.
+	rps := c.baseRPS
+	if c.robots != nil {
+		if delay := c.robots.CrawlDelay(ctx, urlStr); delay > 0 {
+			if allowed := 1 / delay.Seconds(); allowed < rps {
+				rps = allowed
+			}
+		}
 	}
 
-	// Parse URL
+	c.limiterMu.Lock()
+	defer c.limiterMu.Unlock()
+	if limiter, ok := c.limiters[host]; ok {
+		return limiter
+	}
+	// Burst of 1 to ensure strict rate limiting
+	limiter = rate.NewLimiter(rate.Limit(rps), 1)
+	c.limiters[host] = limiter
+	return limiter
+}
+
+// Throttle blocks until urlStr's host admits a request under its rate
+// limiter, without performing one itself. It's exported so callers that
+// fetch a URL outside of Get — namely the Renderer, which drives a
+// headless browser rather than c.httpClient — still respect the same
+// per-host Crawl-delay.
+func (c *Client) Throttle(ctx context.Context, urlStr string) error {
 	reqURL, err := url.Parse(urlStr)
 	if err != nil {
+		return err
+	}
+	limiter := c.limiterFor(ctx, urlStr, reqURL.Hostname())
+	return limiter.Wait(ctx)
+}
+
+// doRequest is the innermost Handler: it performs the actual HTTP round
+// trip. Middleware registered via Use wraps around it.
+func (c *Client) doRequest(ctx context.Context, req *http.Request) (*http.Response, error) {
+	return c.httpClient.Do(req)
+}
+
+// Get performs a GET request with rate limiting and custom headers. When the
+// client has an Archiver configured, the raw request and response are teed
+// into a WARC record before the body is handed back to the caller.
+func (c *Client) Get(ctx context.Context, urlStr string) (*http.Response, error) {
+	if err := c.Throttle(ctx, urlStr); err != nil {
 		return nil, err
 	}
 
 	// Create request with context
-	req, err := http.NewRequestWithContext(ctx, "GET", reqURL.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", urlStr, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -93,15 +216,63 @@ func (c *Client) Get(ctx context.Context, urlStr string) (*http.Response, error)
 		req.Header.Set(key, value)
 	}
 
-	// Perform request
-	return c.httpClient.Do(req)
+	// Perform request through the middleware chain
+	resp, err := c.handler(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.archiver != nil {
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if err := c.archiver.Record(req, resp, body); err != nil {
+			return nil, err
+		}
+		// Replace the consumed body so callers can still read it.
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	return resp, nil
 }
 
-// IsInScope checks if a URL is within the target domain scope
-func (c *Client) IsInScope(urlStr string) bool {
+// InScope reports whether urlStr should be followed, given the link's tag
+// and the client's configured scope policy. Related assets are let through
+// cross-host under SameHostPlusRelated, so archived pages aren't missing
+// their images, scripts, or stylesheets.
+func (c *Client) InScope(urlStr string, tag LinkTag) bool {
 	parsedURL, err := url.Parse(urlStr)
 	if err != nil {
 		return false
 	}
-	return parsedURL.Hostname() == c.baseURL.Hostname()
+
+	switch c.scopePolicy {
+	case SameHostPlusRelated:
+		if tag == TagRelated {
+			return true
+		}
+		return parsedURL.Hostname() == c.baseURL.Hostname()
+	case SeedPrefix:
+		if parsedURL.Scheme != c.baseURL.Scheme || parsedURL.Host != c.baseURL.Host {
+			return false
+		}
+		return pathHasPrefix(parsedURL.Path, c.baseURL.Path)
+	case Regexp:
+		return c.scopeRegexp != nil && c.scopeRegexp.MatchString(urlStr)
+	default: // SameHost
+		return parsedURL.Hostname() == c.baseURL.Hostname()
+	}
+}
+
+// pathHasPrefix reports whether path starts with prefix at a path-segment
+// boundary, so a seed path of "/blog" matches "/blog/post" but not
+// "/blog2". An empty or root prefix matches every path.
+func pathHasPrefix(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		return true
+	}
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
 }
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -22,11 +23,14 @@ type CrawlResult struct {
 type Crawler struct {
 	client     *Client
 	parser     *Parser
+	archiver   *Archiver
+	renderer   Renderer
 	maxDepth   int
 	workers    int
 	results    chan CrawlResult
-	visited    sync.Map
-	queue      chan string
+	queue      Queue
+	pipelines  []Pipeline
+	inFlight   int64 // URLs enqueued but not yet processed; 0 means done
 	wg         sync.WaitGroup
 	stopChan   chan struct{}
 	ctx        context.Context
@@ -42,16 +46,38 @@ type CrawlerConfig struct {
 	MaxRedirects      int
 	Headers           map[string]string
 	RequestsPerSecond float64
+	OutputWARC        string      // path to write a gzipped WARC file, if set
+	StateDir          string      // path to a directory for a resumable on-disk queue, if set
+	ScopePolicy       ScopePolicy // defaults to SameHost
+	ScopeRegexp       string      // pattern used when ScopePolicy is Regexp
+	RespectRobots     bool        // enforce robots.txt Disallow/Allow and Crawl-delay
+	UserAgentToken    string      // user-agent token matched against robots.txt groups; defaults to the User-Agent header
+	RenderJS          bool        // after the regular GET, also render primary HTML pages through a headless Chrome pool before extracting links
+	RenderPoolSize    int         // number of concurrent Chrome tabs when RenderJS is set; defaults to 2
 }
 
 // NewCrawler creates a new crawler instance
 func NewCrawler(config CrawlerConfig) (*Crawler, error) {
+	var archiver *Archiver
+	if config.OutputWARC != "" {
+		a, err := NewArchiver(config.OutputWARC)
+		if err != nil {
+			return nil, err
+		}
+		archiver = a
+	}
+
 	client, err := NewClient(ClientConfig{
 		Timeout:           config.Timeout,
 		MaxRedirects:      config.MaxRedirects,
 		Headers:           config.Headers,
 		BaseURL:           config.URL,
 		RequestsPerSecond: config.RequestsPerSecond,
+		Archiver:          archiver,
+		ScopePolicy:       config.ScopePolicy,
+		ScopeRegexp:       config.ScopeRegexp,
+		RespectRobots:     config.RespectRobots,
+		UserAgentToken:    config.UserAgentToken,
 	})
 	if err != nil {
 		return nil, err
@@ -62,33 +88,91 @@ func NewCrawler(config CrawlerConfig) (*Crawler, error) {
 		return nil, err
 	}
 
+	// A persistent queue lets a Ctrl-C'd crawl resume where it left off;
+	// otherwise fall back to an in-memory queue for a one-shot run.
+	var queue Queue
+	if config.StateDir != "" {
+		queue, err = NewBoltQueue(config.StateDir)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		queue = NewMemQueue()
+	}
+
+	// A renderer is only started when the caller asks for JS execution; it
+	// owns a real Chrome process, so it's expensive to keep around otherwise.
+	var renderer Renderer
+	if config.RenderJS {
+		poolSize := config.RenderPoolSize
+		if poolSize < 1 {
+			poolSize = 2
+		}
+		renderer, err = NewChromeRenderer(poolSize)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Create a context with timeout for the entire crawling process
 	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
 
 	return &Crawler{
 		client:     client,
 		parser:     parser,
+		archiver:   archiver,
+		renderer:   renderer,
+		queue:      queue,
 		maxDepth:   config.MaxDepth,
 		workers:    config.Workers,
 		results:    make(chan CrawlResult, 1000),
-		queue:      make(chan string, 1000),
 		stopChan:   make(chan struct{}),
 		ctx:        ctx,
 		cancelFunc: cancel,
 	}, nil
 }
 
-// Start begins the crawling process
+// Use registers middleware that wraps every HTTP request the crawler
+// makes. Must be called before Start.
+func (c *Crawler) Use(mw ...Middleware) {
+	c.client.Use(mw...)
+}
+
+// Pipe registers pipelines that post-process each CrawlResult before it is
+// emitted. Must be called before Start.
+func (c *Crawler) Pipe(p ...Pipeline) {
+	c.pipelines = append(c.pipelines, p...)
+}
+
+// emit runs result through every registered pipeline, returning false if
+// any of them rejects it.
+func (c *Crawler) emit(result CrawlResult) bool {
+	for _, p := range c.pipelines {
+		if err := p(result); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Start begins the crawling process. The initial URL is re-added first (it
+// is skipped automatically if already seen); if the queue was resumed from a
+// previous run, any URLs left in its frontier sit ahead of newly added ones
+// and are processed first.
 func (c *Crawler) Start() <-chan CrawlResult {
+	c.queue.Enqueue(c.client.baseURL.String(), 0, TagPrimary)
+	c.seedSitemaps()
+
+	if pending, err := c.queue.Pending(); err == nil {
+		atomic.StoreInt64(&c.inFlight, int64(pending))
+	}
+
 	// Start worker pool
 	for i := 0; i < c.workers; i++ {
 		c.wg.Add(1)
 		go c.worker()
 	}
 
-	// Start with the initial URL
-	c.queue <- c.client.baseURL.String()
-
 	// Start a goroutine to close the results channel when crawling is done
 	go func() {
 		c.wg.Wait()
@@ -98,15 +182,62 @@ func (c *Crawler) Start() <-chan CrawlResult {
 	return c.results
 }
 
+// seedSitemaps probes /robots.txt for Sitemap: directives and the
+// conventional /sitemap.xml location on the seed host, enqueueing any it
+// finds so the frontier isn't limited to linked pages.
+func (c *Crawler) seedSitemaps() {
+	base := c.client.baseURL.Scheme + "://" + c.client.baseURL.Host
+
+	for _, sitemapURL := range c.sitemapDirectivesFromRobots(base + "/robots.txt") {
+		c.enqueue(sitemapURL, 0, TagPrimary)
+	}
+	c.enqueue(base+"/sitemap.xml", 0, TagPrimary)
+}
+
+// sitemapDirectivesFromRobots fetches robotsURL and returns the value of
+// every "Sitemap:" directive it contains.
+func (c *Crawler) sitemapDirectivesFromRobots(robotsURL string) []string {
+	resp, err := c.client.Get(c.ctx, robotsURL)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil
+	}
+
+	var sitemaps []string
+	for _, line := range strings.Split(string(body), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(strings.ToLower(line), "sitemap:") {
+			continue
+		}
+		if value := strings.TrimSpace(line[len("sitemap:"):]); value != "" {
+			sitemaps = append(sitemaps, value)
+		}
+	}
+	return sitemaps
+}
+
 // Stop gracefully stops the crawler
 func (c *Crawler) Stop() {
 	c.cancelFunc() // Cancel the context
 	close(c.stopChan)
-	close(c.queue)
 	c.wg.Wait()
+	c.queue.Close()
+
+	if c.archiver != nil {
+		c.archiver.Close()
+	}
+	if c.renderer != nil {
+		c.renderer.Close()
+	}
 }
 
-// worker processes URLs from the queue
+// worker pulls URLs from the queue and processes them, until the frontier
+// is empty and no other worker still has URLs in flight.
 func (c *Crawler) worker() {
 	defer c.wg.Done()
 
@@ -116,108 +247,157 @@ func (c *Crawler) worker() {
 			return
 		case <-c.stopChan:
 			return
-		case urlStr, ok := <-c.queue:
-			if !ok {
+		default:
+		}
+
+		urlStr, depth, tag, ok, err := c.queue.Dequeue()
+		if err != nil {
+			return
+		}
+		if !ok {
+			if atomic.LoadInt64(&c.inFlight) == 0 {
 				return
 			}
-			c.processURL(urlStr, 0)
+			time.Sleep(25 * time.Millisecond)
+			continue
 		}
+
+		c.processURL(urlStr, depth, tag)
+		atomic.AddInt64(&c.inFlight, -1)
 	}
 }
 
-// processURL handles a single URL
-func (c *Crawler) processURL(urlStr string, depth int) {
-	// Check if we've already visited this URL
-	if _, visited := c.visited.LoadOrStore(urlStr, true); visited {
-		return
+// enqueue adds a newly discovered URL to the frontier and tracks it as
+// in flight, unless it has already been seen.
+func (c *Crawler) enqueue(urlStr string, depth int, tag LinkTag) {
+	added, err := c.queue.Enqueue(urlStr, depth, tag)
+	if err == nil && added {
+		atomic.AddInt64(&c.inFlight, 1)
 	}
+}
 
+// processURL handles a single URL
+func (c *Crawler) processURL(urlStr string, depth int, tag LinkTag) {
 	// Check depth limit
 	if depth > c.maxDepth {
 		return
 	}
 
 	// Check if URL is in scope
-	if !c.client.IsInScope(urlStr) {
+	if !c.client.InScope(urlStr, tag) {
 		return
 	}
 
-	// Fetch the URL using the crawler's context
+	// Fetch the URL using the crawler's context. This always goes through
+	// Client.Get first — even when a renderer is configured — so the raw
+	// response is rate limited, robots-checked, and teed to the Archiver
+	// like any other request, and so routing to the renderer can be
+	// decided from the real Content-Type instead of guessing from the
+	// link tag alone.
 	resp, err := c.client.Get(c.ctx, urlStr)
 	if err != nil {
 		return
 	}
 	defer resp.Body.Close()
 
-	// Record the result
-	c.results <- CrawlResult{
+	c.queue.MarkDone(urlStr, resp.StatusCode)
+
+	contentType := getContentType(resp.Header.Get("Content-Type"))
+
+	// Record the result, unless a pipeline rejects it
+	result := CrawlResult{
 		URL:       urlStr,
 		Depth:     depth,
 		Status:    resp.StatusCode,
 		Timestamp: time.Now(),
-		Type:      getContentType(resp.Header.Get("Content-Type")),
+		Type:      contentType,
+	}
+	if c.emit(result) {
+		c.results <- result
 	}
 
-	// Process content based on type
-	if resp.StatusCode == http.StatusOK {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return
-		}
+	if resp.StatusCode != http.StatusOK {
+		return
+	}
 
-		// Create a new reader for the body since we've already read it
-		bodyReader := strings.NewReader(string(body))
-
-		switch getContentType(resp.Header.Get("Content-Type")) {
-		case "html":
-			urls, err := c.parser.ExtractURLs(bodyReader)
-			if err == nil {
-				for _, newURL := range urls {
-					select {
-					case c.queue <- newURL:
-						// Process the new URL at depth + 1
-						go c.processURL(newURL, depth+1)
-					case <-c.ctx.Done():
-						return
-					default:
-						// Queue is full, skip this URL
-					}
-				}
-			}
-		case "css":
-			urls := c.parser.ExtractCSSURLs(string(body))
-			for _, newURL := range urls {
-				select {
-				case c.queue <- newURL:
-					// Process the new URL at depth + 1
-					go c.processURL(newURL, depth+1)
-				case <-c.ctx.Done():
-					return
-				default:
-					// Queue is full, skip this URL
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return
+	}
+
+	// Primary HTML pages are re-rendered through headless Chrome when
+	// configured, so client-side JS has a chance to assemble the DOM
+	// before extraction; related assets (css/js/images) are never worth
+	// the cost of a render. Falls back to the statically fetched body
+	// above if the render itself fails. The render is a second network
+	// round trip to the same host outside of Client.Get, so it still
+	// waits on that host's rate limiter first.
+	if c.renderer != nil && tag == TagPrimary && contentType == "html" {
+		if err := c.client.Throttle(c.ctx, urlStr); err == nil {
+			if rendered, err := c.renderer.Render(c.ctx, urlStr); err == nil {
+				c.enqueueHTMLLinks(rendered.HTML, depth)
+				for _, newURL := range rendered.NetworkURLs {
+					c.enqueue(newURL, depth, TagRelated)
 				}
+				return
 			}
-		case "javascript":
-			urls := c.parser.ExtractJSURLs(string(body))
-			for _, newURL := range urls {
-				select {
-				case c.queue <- newURL:
-					// Process the new URL at depth + 1
-					go c.processURL(newURL, depth+1)
-				case <-c.ctx.Done():
-					return
-				default:
-					// Queue is full, skip this URL
-				}
+		}
+	}
+
+	switch contentType {
+	case "html":
+		c.enqueueHTMLLinks(string(body), depth)
+	case "css":
+		urls := c.parser.ExtractCSSURLs(string(body))
+		for _, newURL := range urls {
+			c.enqueue(newURL, depth, TagRelated)
+		}
+	case "javascript":
+		urls := c.parser.ExtractJSURLs(string(body))
+		for _, newURL := range urls {
+			c.enqueue(newURL, depth, TagRelated)
+		}
+	case "xml":
+		// A sitemap index, a urlset, and a feed are all valid XML
+		// documents; try sitemap parsing first and fall back to feed
+		// parsing if it yields nothing.
+		urls, err := c.parser.ExtractSitemapURLs(strings.NewReader(string(body)))
+		if err != nil || len(urls) == 0 {
+			if feedURLs, err := c.parser.ExtractFeedURLs(strings.NewReader(string(body))); err == nil {
+				urls = feedURLs
 			}
 		}
+		for _, newURL := range urls {
+			c.enqueue(newURL, depth+1, TagPrimary)
+		}
+	}
+}
+
+// enqueueHTMLLinks extracts links from an HTML document and enqueues
+// them, advancing depth only for primary navigation links.
+func (c *Crawler) enqueueHTMLLinks(body string, depth int) {
+	links, err := c.parser.ExtractURLs(strings.NewReader(body))
+	if err != nil {
+		return
+	}
+	for _, link := range links {
+		// Only primary navigation links advance the depth; related
+		// assets are always fetched at the same depth.
+		childDepth := depth
+		if link.Tag == TagPrimary {
+			childDepth = depth + 1
+		}
+		c.enqueue(link.URL, childDepth, link.Tag)
 	}
 }
 
-// getContentType determines the content type from the Content-Type header
+// getContentType determines the content type from the Content-Type header.
+// image/svg+xml and application/xhtml+xml must be checked ahead of the
+// generic "xml" case, or SVGs and XHTML pages get routed into sitemap/feed
+// parsing instead of image handling and HTML link extraction.
 func getContentType(contentType string) string {
 	switch {
-	case strings.Contains(contentType, "text/html"):
+	case strings.Contains(contentType, "text/html"), strings.Contains(contentType, "xhtml"):
 		return "html"
 	case strings.Contains(contentType, "text/css"):
 		return "css"
@@ -225,6 +405,8 @@ func getContentType(contentType string) string {
 		return "javascript"
 	case strings.Contains(contentType, "image/"):
 		return "image"
+	case strings.Contains(contentType, "xml"):
+		return "xml"
 	default:
 		return "other"
 	}
@@ -0,0 +1,74 @@
+package crawler
+
+import "sync"
+
+// MemQueue is an in-memory Queue used when no --state directory is
+// configured. It does not survive interruption.
+type MemQueue struct {
+	mu       sync.Mutex
+	frontier []frontierEntry
+	seen     map[string]int
+	done     map[string]int
+}
+
+// NewMemQueue creates an empty in-memory Queue.
+func NewMemQueue() *MemQueue {
+	return &MemQueue{
+		seen: make(map[string]int),
+		done: make(map[string]int),
+	}
+}
+
+// Enqueue adds url to the frontier at depth, unless it has already been seen.
+func (q *MemQueue) Enqueue(url string, depth int, tag LinkTag) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.seen[url]; ok {
+		return false, nil
+	}
+	q.seen[url] = depth
+	q.frontier = append(q.frontier, frontierEntry{URL: url, Depth: depth, Tag: tag})
+	return true, nil
+}
+
+// Dequeue removes and returns the oldest pending URL in the frontier.
+func (q *MemQueue) Dequeue() (string, int, LinkTag, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.frontier) == 0 {
+		return "", 0, TagPrimary, false, nil
+	}
+	entry := q.frontier[0]
+	q.frontier = q.frontier[1:]
+	return entry.URL, entry.Depth, entry.Tag, true, nil
+}
+
+// MarkDone records the final status code for url.
+func (q *MemQueue) MarkDone(url string, status int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.done[url] = status
+	return nil
+}
+
+// Seen reports whether url has already been enqueued.
+func (q *MemQueue) Seen(url string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	_, ok := q.seen[url]
+	return ok
+}
+
+// Pending returns the number of URLs currently sitting in the frontier.
+func (q *MemQueue) Pending() (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.frontier), nil
+}
+
+// Close is a no-op; MemQueue holds no external resources.
+func (q *MemQueue) Close() error {
+	return nil
+}
@@ -0,0 +1,32 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+)
+
+// Handler performs a single HTTP round trip.
+type Handler func(ctx context.Context, req *http.Request) (*http.Response, error)
+
+// Middleware wraps a Handler to add cross-cutting behavior — decompression,
+// cookies, robots.txt enforcement, redirects, dedup — to every request,
+// without forking the crawler core.
+type Middleware func(next Handler) Handler
+
+// Pipeline post-processes a CrawlResult before it reaches the results
+// channel. Returning an error drops that result from the output, so
+// pipelines can double as output sinks or filters.
+type Pipeline func(CrawlResult) error
+
+// Use registers middleware that wraps every HTTP request made through Get.
+// Middleware runs in registration order, outermost first. Must be called
+// before Start.
+func (c *Client) Use(mw ...Middleware) {
+	c.middlewares = append(c.middlewares, mw...)
+
+	h := c.doRequest
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		h = c.middlewares[i](h)
+	}
+	c.handler = h
+}
@@ -0,0 +1,138 @@
+package crawler
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// ErrDuplicateURL is returned by DedupMiddleware for a URL already fetched
+// by this client.
+var ErrDuplicateURL = errors.New("crawler: duplicate URL")
+
+// DecompressionMiddleware transparently decodes gzip, deflate, and brotli
+// response bodies, so downstream extraction always sees plain text.
+func DecompressionMiddleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			req.Header.Set("Accept-Encoding", "gzip, deflate, br")
+
+			resp, err := next(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+
+			switch resp.Header.Get("Content-Encoding") {
+			case "gzip":
+				gz, err := gzip.NewReader(resp.Body)
+				if err != nil {
+					return nil, err
+				}
+				resp.Body = gz
+			case "deflate":
+				resp.Body = flate.NewReader(resp.Body)
+			case "br":
+				resp.Body = io.NopCloser(brotli.NewReader(resp.Body))
+			default:
+				return resp, nil
+			}
+			resp.Header.Del("Content-Encoding")
+			return resp, nil
+		}
+	}
+}
+
+// NewCookieJar creates an in-memory cookie jar suitable for
+// CookieJarMiddleware.
+func NewCookieJar() http.CookieJar {
+	jar, _ := cookiejar.New(nil)
+	return jar
+}
+
+// CookieJarMiddleware attaches cookies from jar to outgoing requests and
+// stores cookies from Set-Cookie response headers back into it, so a login
+// or session cookie survives across the crawl.
+func CookieJarMiddleware(jar http.CookieJar) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			for _, cookie := range jar.Cookies(req.URL) {
+				req.AddCookie(cookie)
+			}
+
+			resp, err := next(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+
+			if cookies := resp.Cookies(); len(cookies) > 0 {
+				jar.SetCookies(req.URL, cookies)
+			}
+			return resp, nil
+		}
+	}
+}
+
+// RedirectMiddleware follows Location headers itself, up to maxRedirects
+// hops, so every other registered middleware (cookies, robots, dedup) also
+// runs against intermediate redirect hops.
+func RedirectMiddleware(maxRedirects int) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			for hop := 0; ; hop++ {
+				resp, err := next(ctx, req)
+				if err != nil {
+					return nil, err
+				}
+
+				if resp.StatusCode < 300 || resp.StatusCode >= 400 || hop >= maxRedirects {
+					return resp, nil
+				}
+
+				location := resp.Header.Get("Location")
+				if location == "" {
+					return resp, nil
+				}
+
+				nextURL, err := req.URL.Parse(location)
+				if err != nil {
+					return resp, nil
+				}
+				resp.Body.Close()
+
+				req = req.Clone(ctx)
+				req.URL = nextURL
+				req.Host = ""
+			}
+		}
+	}
+}
+
+// DedupMiddleware rejects a URL this client has already fetched, returning
+// ErrDuplicateURL, so the same asset isn't requested twice in one crawl.
+func DedupMiddleware() Middleware {
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			key := req.URL.String()
+
+			mu.Lock()
+			if seen[key] {
+				mu.Unlock()
+				return nil, ErrDuplicateURL
+			}
+			seen[key] = true
+			mu.Unlock()
+
+			return next(ctx, req)
+		}
+	}
+}
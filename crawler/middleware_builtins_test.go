@@ -0,0 +1,54 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+)
+
+// TestMiddlewareChainDecompressesAndDedupes chains DecompressionMiddleware
+// and DedupMiddleware the same way NewClient does, and checks both
+// behaviors end to end: a gzip body is decoded for the caller, and a
+// repeated URL is rejected without reaching the inner handler.
+func TestMiddlewareChainDecompressesAndDedupes(t *testing.T) {
+	var calls int
+	base := Handler(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+		calls++
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		gz.Write([]byte("payload"))
+		gz.Close()
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Encoding": []string{"gzip"}},
+			Body:       io.NopCloser(bytes.NewReader(buf.Bytes())),
+		}, nil
+	})
+
+	handler := DecompressionMiddleware()(DedupMiddleware()(base))
+
+	req1, _ := http.NewRequest("GET", "http://example.com/a", nil)
+	resp, err := handler(context.Background(), req1)
+	if err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "payload" {
+		t.Fatalf("got body %q, want %q", body, "payload")
+	}
+	if enc := resp.Header.Get("Content-Encoding"); enc != "" {
+		t.Fatalf("Content-Encoding should be stripped after decoding, got %q", enc)
+	}
+
+	req2, _ := http.NewRequest("GET", "http://example.com/a", nil)
+	if _, err := handler(context.Background(), req2); !errors.Is(err, ErrDuplicateURL) {
+		t.Fatalf("second request: got err %v, want ErrDuplicateURL", err)
+	}
+	if calls != 1 {
+		t.Fatalf("inner handler called %d times, want 1 (dedup should have blocked the repeat)", calls)
+	}
+}
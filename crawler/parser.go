@@ -1,6 +1,7 @@
 package crawler
 
 import (
+	"encoding/xml"
 	"io"
 	"net/url"
 	"regexp"
@@ -14,6 +15,25 @@ type Parser struct {
 	baseURL *url.URL
 }
 
+// LinkTag classifies a discovered URL by how it should affect crawl scope.
+type LinkTag int
+
+const (
+	// TagPrimary marks navigation links — <a href>, <form action>, and
+	// RSS/Atom feed <link rel="alternate"> — which advance the crawl depth.
+	TagPrimary LinkTag = iota
+	// TagRelated marks subresources — other <link>s, <img src>, <script src>, CSS
+	// url(...), and JS fetch targets — which are fetched alongside an
+	// in-scope page without advancing the crawl depth.
+	TagRelated
+)
+
+// Link is a URL discovered during extraction, tagged with its role.
+type Link struct {
+	URL string
+	Tag LinkTag
+}
+
 // NewParser creates a new parser instance
 func NewParser(baseURL string) (*Parser, error) {
 	parsedURL, err := url.Parse(baseURL)
@@ -23,9 +43,14 @@ func NewParser(baseURL string) (*Parser, error) {
 	return &Parser{baseURL: parsedURL}, nil
 }
 
-// ExtractURLs parses HTML content and extracts all relevant URLs
-func (p *Parser) ExtractURLs(reader io.Reader) ([]string, error) {
-	var urls []string
+// ExtractURLs parses HTML content and extracts all relevant URLs, tagged
+// as primary navigation or related subresources. A page-level
+// <meta name="robots" content="nofollow|noindex"> suppresses all primary
+// links (related assets are still returned), and individual
+// <a rel="nofollow"> links are dropped.
+func (p *Parser) ExtractURLs(reader io.Reader) ([]Link, error) {
+	var links []Link
+	pageNofollow := false
 	doc, err := html.Parse(reader)
 	if err != nil {
 		return nil, err
@@ -36,25 +61,32 @@ func (p *Parser) ExtractURLs(reader io.Reader) ([]string, error) {
 	extractNode = func(n *html.Node) {
 		if n.Type == html.ElementNode {
 			switch n.Data {
+			case "meta":
+				if strings.EqualFold(getAttr(n, "name"), "robots") {
+					content := strings.ToLower(getAttr(n, "content"))
+					if strings.Contains(content, "nofollow") || strings.Contains(content, "noindex") {
+						pageNofollow = true
+					}
+				}
 			case "a":
-				if href := getAttr(n, "href"); href != "" {
-					urls = append(urls, p.normalizeURL(href))
+				if href := getAttr(n, "href"); href != "" && !hasRelNofollow(n) {
+					links = append(links, Link{URL: p.normalizeURL(href), Tag: TagPrimary})
+				}
+			case "form":
+				if action := getAttr(n, "action"); action != "" {
+					links = append(links, Link{URL: p.normalizeURL(action), Tag: TagPrimary})
 				}
 			case "script":
 				if src := getAttr(n, "src"); src != "" {
-					urls = append(urls, p.normalizeURL(src))
+					links = append(links, Link{URL: p.normalizeURL(src), Tag: TagRelated})
 				}
 			case "link":
 				if href := getAttr(n, "href"); href != "" {
-					urls = append(urls, p.normalizeURL(href))
+					links = append(links, Link{URL: p.normalizeURL(href), Tag: linkTagFor(n)})
 				}
 			case "img":
 				if src := getAttr(n, "src"); src != "" {
-					urls = append(urls, p.normalizeURL(src))
-				}
-			case "form":
-				if action := getAttr(n, "action"); action != "" {
-					urls = append(urls, p.normalizeURL(action))
+					links = append(links, Link{URL: p.normalizeURL(src), Tag: TagRelated})
 				}
 			}
 		}
@@ -64,7 +96,41 @@ func (p *Parser) ExtractURLs(reader io.Reader) ([]string, error) {
 	}
 	extractNode(doc)
 
-	return urls, nil
+	if pageNofollow {
+		kept := links[:0]
+		for _, link := range links {
+			if link.Tag == TagRelated {
+				kept = append(kept, link)
+			}
+		}
+		links = kept
+	}
+
+	return links, nil
+}
+
+// linkTagFor classifies a <link> node. An RSS/Atom feed discovered via
+// <link rel="alternate" type="application/rss+xml|atom+xml"> is tagged
+// primary, the same as a sitemap: it's a source of further pages to
+// crawl, not a page asset. Everything else (stylesheets, icons, canonical
+// links, ...) stays related.
+func linkTagFor(n *html.Node) LinkTag {
+	rel := strings.ToLower(getAttr(n, "rel"))
+	feedType := strings.ToLower(getAttr(n, "type"))
+	if rel == "alternate" && (strings.Contains(feedType, "rss+xml") || strings.Contains(feedType, "atom+xml")) {
+		return TagPrimary
+	}
+	return TagRelated
+}
+
+// hasRelNofollow reports whether an <a> node carries rel="nofollow".
+func hasRelNofollow(n *html.Node) bool {
+	for _, token := range strings.Fields(getAttr(n, "rel")) {
+		if strings.EqualFold(token, "nofollow") {
+			return true
+		}
+	}
+	return false
 }
 
 // ExtractCSSURLs extracts URLs from CSS content
@@ -103,6 +169,94 @@ func (p *Parser) ExtractJSURLs(content string) []string {
 	return urls
 }
 
+// ExtractSitemapURLs parses a sitemap.xml or sitemap index document and
+// returns the URLs it lists. A sitemap index yields the child sitemap
+// URLs rather than page URLs; callers should fetch and parse those in turn.
+func (p *Parser) ExtractSitemapURLs(reader io.Reader) ([]string, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var index struct {
+		XMLName  xml.Name `xml:"sitemapindex"`
+		Sitemaps []struct {
+			Loc string `xml:"loc"`
+		} `xml:"sitemap"`
+	}
+	if err := xml.Unmarshal(data, &index); err == nil {
+		urls := make([]string, 0, len(index.Sitemaps))
+		for _, sitemap := range index.Sitemaps {
+			urls = append(urls, p.normalizeURL(sitemap.Loc))
+		}
+		return urls, nil
+	}
+
+	var set struct {
+		XMLName xml.Name `xml:"urlset"`
+		URLs    []struct {
+			Loc string `xml:"loc"`
+		} `xml:"url"`
+	}
+	if err := xml.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	urls := make([]string, 0, len(set.URLs))
+	for _, u := range set.URLs {
+		urls = append(urls, p.normalizeURL(u.Loc))
+	}
+	return urls, nil
+}
+
+// ExtractFeedURLs parses an RSS or Atom feed document and returns the
+// URLs of its items/entries.
+func (p *Parser) ExtractFeedURLs(reader io.Reader) ([]string, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var rss struct {
+		XMLName xml.Name `xml:"rss"`
+		Channel struct {
+			Items []struct {
+				Link string `xml:"link"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(data, &rss); err == nil {
+		urls := make([]string, 0, len(rss.Channel.Items))
+		for _, item := range rss.Channel.Items {
+			urls = append(urls, p.normalizeURL(item.Link))
+		}
+		return urls, nil
+	}
+
+	var atom struct {
+		XMLName xml.Name `xml:"feed"`
+		Entries []struct {
+			Links []struct {
+				Href string `xml:"href,attr"`
+				Rel  string `xml:"rel,attr"`
+			} `xml:"link"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(data, &atom); err != nil {
+		return nil, err
+	}
+
+	var urls []string
+	for _, entry := range atom.Entries {
+		for _, link := range entry.Links {
+			if link.Rel == "" || link.Rel == "alternate" {
+				urls = append(urls, p.normalizeURL(link.Href))
+			}
+		}
+	}
+	return urls, nil
+}
+
 // normalizeURL converts a relative URL to an absolute URL
 func (p *Parser) normalizeURL(urlStr string) string {
 	if urlStr == "" {
@@ -0,0 +1,28 @@
+package crawler
+
+// frontierEntry is a URL queued for fetching at a given depth.
+type frontierEntry struct {
+	URL   string
+	Depth int
+	Tag   LinkTag
+}
+
+// Queue is a restartable store of crawl frontier state. Implementations
+// must be safe for concurrent use by multiple workers.
+type Queue interface {
+	// Enqueue adds url to the frontier at depth with the given tag. added
+	// is false if url has already been seen, in which case the frontier is
+	// left unchanged.
+	Enqueue(url string, depth int, tag LinkTag) (added bool, err error)
+	// Dequeue removes and returns the oldest pending URL, its depth, and
+	// its tag. ok is false when the frontier is currently empty.
+	Dequeue() (url string, depth int, tag LinkTag, ok bool, err error)
+	// MarkDone records the final status code for url.
+	MarkDone(url string, status int) error
+	// Seen reports whether url has already been enqueued.
+	Seen(url string) bool
+	// Pending returns the number of URLs currently sitting in the frontier.
+	Pending() (int, error)
+	// Close releases the underlying store.
+	Close() error
+}
@@ -0,0 +1,20 @@
+package crawler
+
+import "context"
+
+// RenderResult is the outcome of rendering a page through a headless
+// browser: the post-render DOM, plus every subresource URL observed over
+// the network while the page was loading.
+type RenderResult struct {
+	HTML        string
+	NetworkURLs []string
+}
+
+// Renderer fetches a page through a real browser engine instead of a bare
+// HTTP GET, for pages whose content is assembled by client-side JS after
+// load. Implementations should wait for the page to go network-idle
+// before returning, so late-loading subresources are captured.
+type Renderer interface {
+	Render(ctx context.Context, urlStr string) (RenderResult, error)
+	Close() error
+}
@@ -0,0 +1,149 @@
+package crawler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// networkIdleTimeout is how long a tab must go without a new network
+// request before ChromeRenderer considers the page finished loading.
+const networkIdleTimeout = 500 * time.Millisecond
+
+// ChromeRenderer is a Renderer backed by a pool of headless Chrome tabs
+// managed by chromedp. It is used for pages whose content is assembled by
+// client-side JS, where a plain Client.Get would only see the initial
+// document shell.
+type ChromeRenderer struct {
+	allocCtx    context.Context
+	allocCancel context.CancelFunc
+
+	mu   sync.Mutex
+	pool []context.Context // idle tab contexts, reused across Render calls
+}
+
+// NewChromeRenderer launches a headless Chrome instance and pre-warms a
+// pool of poolSize tabs for concurrent rendering.
+func NewChromeRenderer(poolSize int) (*ChromeRenderer, error) {
+	if poolSize < 1 {
+		poolSize = 1
+	}
+
+	allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), chromedp.DefaultExecAllocatorOptions[:]...)
+
+	r := &ChromeRenderer{
+		allocCtx:    allocCtx,
+		allocCancel: allocCancel,
+	}
+
+	for i := 0; i < poolSize; i++ {
+		tabCtx, _ := chromedp.NewContext(allocCtx)
+		if err := chromedp.Run(tabCtx); err != nil {
+			r.Close()
+			return nil, err
+		}
+		r.pool = append(r.pool, tabCtx)
+	}
+
+	return r, nil
+}
+
+// acquire blocks until a tab is free, then removes it from the pool.
+func (r *ChromeRenderer) acquire() context.Context {
+	for {
+		r.mu.Lock()
+		if n := len(r.pool); n > 0 {
+			tabCtx := r.pool[n-1]
+			r.pool = r.pool[:n-1]
+			r.mu.Unlock()
+			return tabCtx
+		}
+		r.mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// release returns a tab to the pool for reuse.
+func (r *ChromeRenderer) release(tabCtx context.Context) {
+	r.mu.Lock()
+	r.pool = append(r.pool, tabCtx)
+	r.mu.Unlock()
+}
+
+// Render navigates a pooled tab to urlStr, waits for the network to go
+// idle, and returns the rendered DOM plus every subresource URL Chrome
+// requested while the page loaded.
+func (r *ChromeRenderer) Render(ctx context.Context, urlStr string) (RenderResult, error) {
+	tabCtx := r.acquire()
+	defer r.release(tabCtx)
+
+	runCtx, cancel := context.WithCancel(tabCtx)
+	defer cancel()
+
+	var (
+		mu          sync.Mutex
+		networkURLs []string
+		lastSeen    = time.Now()
+	)
+	chromedp.ListenTarget(runCtx, func(ev interface{}) {
+		if e, ok := ev.(*network.EventRequestWillBeSent); ok {
+			mu.Lock()
+			networkURLs = append(networkURLs, e.Request.URL)
+			lastSeen = time.Now()
+			mu.Unlock()
+		}
+	})
+
+	var html string
+	err := chromedp.Run(runCtx,
+		network.Enable(),
+		chromedp.Navigate(urlStr),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			for {
+				mu.Lock()
+				idleFor := time.Since(lastSeen)
+				mu.Unlock()
+				if idleFor >= networkIdleTimeout {
+					return nil
+				}
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(networkIdleTimeout - idleFor):
+				}
+			}
+		}),
+		chromedp.OuterHTML("html", &html),
+	)
+	if err != nil {
+		return RenderResult{}, err
+	}
+
+	mu.Lock()
+	urls := dedupeStrings(networkURLs)
+	mu.Unlock()
+
+	return RenderResult{HTML: html, NetworkURLs: urls}, nil
+}
+
+// Close shuts down the Chrome instance and every tab in the pool.
+func (r *ChromeRenderer) Close() error {
+	r.allocCancel()
+	return nil
+}
+
+// dedupeStrings removes duplicates from urls while preserving order.
+func dedupeStrings(urls []string) []string {
+	seen := make(map[string]bool, len(urls))
+	out := make([]string, 0, len(urls))
+	for _, u := range urls {
+		if !seen[u] {
+			seen[u] = true
+			out = append(out, u)
+		}
+	}
+	return out
+}
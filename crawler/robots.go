@@ -0,0 +1,127 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/temoto/robotstxt"
+)
+
+// ErrDisallowedByRobots is returned when a request is blocked by the
+// target host's robots.txt.
+var ErrDisallowedByRobots = errors.New("crawler: disallowed by robots.txt")
+
+// hostRobots is the parsed robots.txt state cached for a single host.
+type hostRobots struct {
+	group      *robotstxt.Group
+	crawlDelay time.Duration
+}
+
+// RobotsPolicy fetches and caches each host's robots.txt (parsed with
+// github.com/temoto/robotstxt), and enforces its Disallow/Allow rules and
+// Crawl-delay directive for the configured user agent.
+type RobotsPolicy struct {
+	userAgent string
+	fetch     func(ctx context.Context, urlStr string) (*http.Response, error)
+
+	mu    sync.Mutex
+	hosts map[string]*hostRobots
+}
+
+// NewRobotsPolicy creates a RobotsPolicy that identifies itself as
+// userAgent and fetches robots.txt through fetch (typically a Client's
+// unthrottled request handler, so the fetch itself isn't rate limited).
+func NewRobotsPolicy(userAgent string, fetch func(ctx context.Context, urlStr string) (*http.Response, error)) *RobotsPolicy {
+	return &RobotsPolicy{
+		userAgent: userAgent,
+		fetch:     fetch,
+		hosts:     make(map[string]*hostRobots),
+	}
+}
+
+// hostRobotsFor returns the cached robots.txt state for u's host, fetching
+// and parsing it on first use.
+func (r *RobotsPolicy) hostRobotsFor(ctx context.Context, u *url.URL) (*hostRobots, error) {
+	host := u.Hostname()
+
+	r.mu.Lock()
+	hr, cached := r.hosts[host]
+	r.mu.Unlock()
+	if cached {
+		return hr, nil
+	}
+
+	robotsURL := u.Scheme + "://" + u.Host + "/robots.txt"
+	resp, err := r.fetch(ctx, robotsURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := robotstxt.FromResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	group := data.FindGroup(r.userAgent)
+
+	hr = &hostRobots{group: group, crawlDelay: group.CrawlDelay}
+
+	r.mu.Lock()
+	r.hosts[host] = hr
+	r.mu.Unlock()
+
+	return hr, nil
+}
+
+// Allowed reports whether urlStr may be fetched under its host's robots.txt.
+func (r *RobotsPolicy) Allowed(ctx context.Context, urlStr string) (bool, error) {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return false, err
+	}
+
+	hr, err := r.hostRobotsFor(ctx, u)
+	if err != nil {
+		return false, err
+	}
+	if hr.group == nil {
+		return true, nil
+	}
+	return hr.group.Test(u.Path), nil
+}
+
+// CrawlDelay returns the Crawl-delay directive for urlStr's host, or 0 if
+// the host's robots.txt specifies none.
+func (r *RobotsPolicy) CrawlDelay(ctx context.Context, urlStr string) time.Duration {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return 0
+	}
+
+	hr, err := r.hostRobotsFor(ctx, u)
+	if err != nil {
+		return 0
+	}
+	return hr.crawlDelay
+}
+
+// Middleware returns a Middleware that rejects requests disallowed by this
+// policy with ErrDisallowedByRobots.
+func (r *RobotsPolicy) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			allowed, err := r.Allowed(ctx, req.URL.String())
+			if err != nil {
+				return nil, err
+			}
+			if !allowed {
+				return nil, ErrDisallowedByRobots
+			}
+			return next(ctx, req)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package crawler
+
+// ScopePolicy decides which discovered links a Crawler follows.
+type ScopePolicy int
+
+const (
+	// SameHost only follows links whose host matches the seed URL's host.
+	SameHost ScopePolicy = iota
+	// SameHostPlusRelated follows same-host primary links, and additionally
+	// fetches related assets (images, scripts, stylesheets) even when they
+	// live on a different host, so archived pages aren't missing assets.
+	SameHostPlusRelated
+	// SeedPrefix follows any link with the seed URL's scheme and host whose
+	// path lies under the seed URL's path, matched at a path-segment
+	// boundary ("/blog" covers "/blog/post" but not "/blog2").
+	SeedPrefix
+	// Regexp follows any link whose URL matches CrawlerConfig.ScopeRegexp.
+	Regexp
+)
@@ -28,6 +28,12 @@ func main() {
 	rate := flag.Float64("r", 10.0, "Requests per second")
 	format := flag.String("o", "tree", "Output format (tree/json/csv)")
 	headers := flag.String("H", "", "Custom headers (comma-separated key=value pairs)")
+	outputWARC := flag.String("output-warc", "", "Write a gzipped WARC file of every request/response (e.g. crawl.warc.gz)")
+	state := flag.String("state", "", "Directory for a resumable on-disk crawl queue (omit for a one-shot, in-memory crawl)")
+	respectRobots := flag.Bool("respect-robots", false, "Enforce robots.txt Disallow/Allow rules and Crawl-delay")
+	userAgentToken := flag.String("user-agent-token", "", "User-agent token matched against robots.txt groups (defaults to the User-Agent header)")
+	renderJS := flag.Bool("render-js", false, "Render primary pages through headless Chrome before extracting links")
+	renderPoolSize := flag.Int("render-pool-size", 2, "Number of concurrent Chrome tabs when --render-js is set")
 	flag.Parse()
 
 	// Validate required arguments
@@ -62,6 +68,12 @@ func main() {
 		MaxRedirects:     10,
 		Headers:          headerMap,
 		RequestsPerSecond: *rate,
+		OutputWARC:       *outputWARC,
+		StateDir:         *state,
+		RespectRobots:    *respectRobots,
+		UserAgentToken:   *userAgentToken,
+		RenderJS:         *renderJS,
+		RenderPoolSize:   *renderPoolSize,
 	}
 
 	// Initialize crawler